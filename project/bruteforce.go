@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// dirTarget описывает одну проверяемую комбинацию базового URL и пути из словаря
+type dirTarget struct {
+	URL  string
+	Base string
+	Path string
+}
+
+// baseline хранит сигнатуру "soft 404" ответа, снятую перед перебором словаря
+type baseline struct {
+	StatusCode int
+	SizeBytes  int64
+	BodyHash   string
+}
+
+// randomPath генерирует случайный UUID-подобный путь для калибровки wildcard-ответов
+func randomPath() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// calibrateBaseline запрашивает случайный несуществующий путь у baseURL и запоминает
+// его статус/размер/хэш тела, чтобы потом отсеять одинаковые wildcard-ответы
+func calibrateBaseline(baseURL string, client *http.Client, cfg ProbeConfig, rc RetryConfig, limiter *RateLimiter, hostLimiter *HostLimiter) (baseline, error) {
+	target := strings.TrimRight(baseURL, "/") + "/" + randomPath()
+	r := checkURLWithRetry(Target{URL: target}, client, cfg, rc, limiter, hostLimiter)
+	if r.Error != "" {
+		return baseline{}, errors.New(r.Error)
+	}
+	return baseline{StatusCode: r.StatusCode, SizeBytes: r.SizeBytes, BodyHash: r.BodyHash}, nil
+}
+
+// looksLikeBaseline сообщает, совпадает ли результат с откалиброванным wildcard-ответом
+func looksLikeBaseline(b baseline, r Result) bool {
+	if b == (baseline{}) {
+		return false
+	}
+	return r.StatusCode == b.StatusCode && r.SizeBytes == b.SizeBytes && r.BodyHash == b.BodyHash
+}
+
+// buildDirTargets разворачивает словарь слов в список путей относительно baseURL,
+// добавляя вариант с каждым из -extensions
+func buildDirTargets(baseURL string, wordlist, extensions []string) []dirTarget {
+	base := strings.TrimRight(baseURL, "/")
+	var targets []dirTarget
+	for _, word := range wordlist {
+		paths := []string{word}
+		for _, ext := range extensions {
+			paths = append(paths, word+ext)
+		}
+		for _, p := range paths {
+			targets = append(targets, dirTarget{URL: base + "/" + p, Base: base, Path: "/" + p})
+		}
+	}
+	return targets
+}
+
+// runDirMode перебирает wordlist относительно каждого baseURL (-mode dir), откалибровав
+// перед этим wildcard/soft-404 baseline, и возвращает только результаты, прошедшие
+// -status-codes и не совпавшие с baseline
+func runDirMode(baseURLs, wordlist, extensions []string, statusCodes []sizeRange, workers int, client *http.Client, cfg ProbeConfig, rc RetryConfig, limiter *RateLimiter, hostLimiter *HostLimiter) []Result {
+	var results []Result
+
+	for _, base := range baseURLs {
+		bl, err := calibrateBaseline(base, client, cfg, rc, limiter, hostLimiter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "калибровка baseline для %s: %v\n", base, err)
+		}
+
+		targets := buildDirTargets(base, wordlist, extensions)
+		targetChan := make(chan dirTarget, len(targets))
+		resChan := make(chan Result, len(targets))
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for t := range targetChan {
+					r := checkURLWithRetry(Target{URL: t.URL}, client, cfg, rc, limiter, hostLimiter)
+					r.BaseURL = t.Base
+					r.Path = t.Path
+					resChan <- r
+				}
+			}()
+		}
+
+		for _, t := range targets {
+			targetChan <- t
+		}
+		close(targetChan)
+
+		go func() {
+			wg.Wait()
+			close(resChan)
+		}()
+
+		for r := range resChan {
+			if looksLikeBaseline(bl, r) {
+				continue
+			}
+			if len(statusCodes) > 0 && !rangesContain(statusCodes, int64(r.StatusCode)) {
+				continue
+			}
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestLooksLikeBaseline(t *testing.T) {
+	bl := baseline{StatusCode: 404, SizeBytes: 512, BodyHash: "abc123"}
+
+	tests := []struct {
+		name string
+		b    baseline
+		r    Result
+		want bool
+	}{
+		{"совпадает полностью", bl, Result{StatusCode: 404, SizeBytes: 512, BodyHash: "abc123"}, true},
+		{"другой статус", bl, Result{StatusCode: 200, SizeBytes: 512, BodyHash: "abc123"}, false},
+		{"другой размер", bl, Result{StatusCode: 404, SizeBytes: 1, BodyHash: "abc123"}, false},
+		{"другой хэш", bl, Result{StatusCode: 404, SizeBytes: 512, BodyHash: "xyz"}, false},
+		{
+			name: "калибровка не удалась (нулевой baseline) не должна совпадать с нулевым результатом",
+			b:    baseline{},
+			r:    Result{StatusCode: 0, SizeBytes: 0, BodyHash: ""},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := looksLikeBaseline(tt.b, tt.r); got != tt.want {
+				t.Errorf("looksLikeBaseline(%+v, %+v) = %v, ожидали %v", tt.b, tt.r, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildDirTargets(t *testing.T) {
+	targets := buildDirTargets("https://example.com/", []string{"admin", "login"}, []string{".php", ".html"})
+
+	want := []dirTarget{
+		{URL: "https://example.com/admin", Base: "https://example.com", Path: "/admin"},
+		{URL: "https://example.com/admin.php", Base: "https://example.com", Path: "/admin.php"},
+		{URL: "https://example.com/admin.html", Base: "https://example.com", Path: "/admin.html"},
+		{URL: "https://example.com/login", Base: "https://example.com", Path: "/login"},
+		{URL: "https://example.com/login.php", Base: "https://example.com", Path: "/login.php"},
+		{URL: "https://example.com/login.html", Base: "https://example.com", Path: "/login.html"},
+	}
+
+	if len(targets) != len(want) {
+		t.Fatalf("buildDirTargets вернул %d целей, ожидали %d: %+v", len(targets), len(want), targets)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Errorf("targets[%d] = %+v, ожидали %+v", i, targets[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+)
+
+// saveCSV сохраняет результаты в CSV: заголовок + одна строка на URL, пригодную для
+// загрузки в таблицы или Prometheus textfile collector
+func saveCSV(results []Result, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	header := []string{
+		"url", "method", "status_code", "ok", "size_bytes", "error",
+		"dns_ms", "connect_ms", "tls_ms", "ttfb_ms", "total_ms",
+		"server", "title", "content_type",
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		row := []string{
+			r.URL, r.Method, strconv.Itoa(r.StatusCode), strconv.FormatBool(r.OK),
+			strconv.FormatInt(r.SizeBytes, 10), r.Error,
+			strconv.FormatInt(r.Timing.DNSMs, 10), strconv.FormatInt(r.Timing.ConnectMs, 10),
+			strconv.FormatInt(r.Timing.TLSMs, 10), strconv.FormatInt(r.Timing.TTFBMs, 10),
+			strconv.FormatInt(r.Timing.TotalMs, 10),
+			r.Server, r.Title, r.ContentType,
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return w.Error()
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sizeRange описывает одну границу диапазона размеров/статусов, например "301-303" или "200"
+type sizeRange struct {
+	from, to int64
+}
+
+func (r sizeRange) contains(v int64) bool {
+	return v >= r.from && v <= r.to
+}
+
+// parseRanges разбирает список через запятую вида "200,301-303" в набор диапазонов
+func parseRanges(raw string) ([]sizeRange, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var ranges []sizeRange
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if idx := strings.Index(part, "-"); idx > 0 {
+			from, err := strconv.ParseInt(part[:idx], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("некорректная граница диапазона %q: %w", part, err)
+			}
+			to, err := strconv.ParseInt(part[idx+1:], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("некорректная граница диапазона %q: %w", part, err)
+			}
+			if from > to {
+				return nil, fmt.Errorf("диапазон %q задом наперёд: %d больше %d", part, from, to)
+			}
+			ranges = append(ranges, sizeRange{from: from, to: to})
+			continue
+		}
+		v, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное значение %q: %w", part, err)
+		}
+		ranges = append(ranges, sizeRange{from: v, to: v})
+	}
+	return ranges, nil
+}
+
+func rangesContain(ranges []sizeRange, v int64) bool {
+	for _, r := range ranges {
+		if r.contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStringList разбирает список подстрок через запятую, используемый -match-string/-filter-string
+func parseStringList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// MatchFilterConfig собирает все -match-*/-filter-* флаги в одну структуру
+type MatchFilterConfig struct {
+	MatchStatus  []sizeRange
+	FilterStatus []sizeRange
+	MatchSize    []sizeRange
+	FilterSize   []sizeRange
+	MatchRegex   *regexp.Regexp
+	FilterRegex  *regexp.Regexp
+	MatchString  []string
+	FilterString []string
+}
+
+// passes сообщает, проходит ли результат все заданные match/filter-правила
+func (c MatchFilterConfig) passes(r Result) bool {
+	if len(c.MatchStatus) > 0 && !rangesContain(c.MatchStatus, int64(r.StatusCode)) {
+		return false
+	}
+	if len(c.FilterStatus) > 0 && rangesContain(c.FilterStatus, int64(r.StatusCode)) {
+		return false
+	}
+	if len(c.MatchSize) > 0 && !rangesContain(c.MatchSize, r.SizeBytes) {
+		return false
+	}
+	if len(c.FilterSize) > 0 && rangesContain(c.FilterSize, r.SizeBytes) {
+		return false
+	}
+	if c.MatchRegex != nil && !c.MatchRegex.MatchString(r.Title) && !matchAnyHeader(c.MatchRegex, r) {
+		return false
+	}
+	if c.FilterRegex != nil && (c.FilterRegex.MatchString(r.Title) || matchAnyHeader(c.FilterRegex, r)) {
+		return false
+	}
+	if len(c.MatchString) > 0 && !containsAny(r, c.MatchString) {
+		return false
+	}
+	if len(c.FilterString) > 0 && containsAny(r, c.FilterString) {
+		return false
+	}
+	return true
+}
+
+// matchAnyHeader проверяет регулярное выражение против заголовков ответа (в дополнение к <title>)
+func matchAnyHeader(re *regexp.Regexp, r Result) bool {
+	for _, v := range r.ResponseHeaders {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny сообщает, встречается ли хотя бы одна из подстрок в URL, <title>, Server или Content-Type
+func containsAny(r Result, substrings []string) bool {
+	haystacks := []string{r.URL, r.Title, r.Server, r.ContentType}
+	for _, s := range substrings {
+		for _, h := range haystacks {
+			if strings.Contains(h, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildMatchFilterConfig разбирает сырые значения -match-*/-filter-* флагов и один раз
+// компилирует регулярные выражения
+func buildMatchFilterConfig(matchStatus, filterStatus, matchSize, filterSize,
+	matchRegex, filterRegex, matchString, filterString string) (MatchFilterConfig, error) {
+	var cfg MatchFilterConfig
+	var err error
+
+	if cfg.MatchStatus, err = parseRanges(matchStatus); err != nil {
+		return cfg, fmt.Errorf("-match-status: %w", err)
+	}
+	if cfg.FilterStatus, err = parseRanges(filterStatus); err != nil {
+		return cfg, fmt.Errorf("-filter-status: %w", err)
+	}
+	if cfg.MatchSize, err = parseRanges(matchSize); err != nil {
+		return cfg, fmt.Errorf("-match-size: %w", err)
+	}
+	if cfg.FilterSize, err = parseRanges(filterSize); err != nil {
+		return cfg, fmt.Errorf("-filter-size: %w", err)
+	}
+	if matchRegex != "" {
+		if cfg.MatchRegex, err = regexp.Compile(matchRegex); err != nil {
+			return cfg, fmt.Errorf("-match-regex: %w", err)
+		}
+	}
+	if filterRegex != "" {
+		if cfg.FilterRegex, err = regexp.Compile(filterRegex); err != nil {
+			return cfg, fmt.Errorf("-filter-regex: %w", err)
+		}
+	}
+	cfg.MatchString = parseStringList(matchString)
+	cfg.FilterString = parseStringList(filterString)
+
+	return cfg, nil
+}
+
+// filterResults применяет MatchFilterConfig к списку результатов, оставляя только прошедшие
+func filterResults(results []Result, cfg MatchFilterConfig) []Result {
+	filtered := results[:0:0]
+	for _, r := range results {
+		if cfg.passes(r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestParseRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []sizeRange
+		wantErr bool
+	}{
+		{name: "пусто", raw: "", want: nil},
+		{name: "одно значение", raw: "200", want: []sizeRange{{from: 200, to: 200}}},
+		{name: "диапазон", raw: "301-303", want: []sizeRange{{from: 301, to: 303}}},
+		{name: "список значений и диапазонов", raw: "200, 301-303, 404", want: []sizeRange{
+			{from: 200, to: 200}, {from: 301, to: 303}, {from: 404, to: 404},
+		}},
+		{name: "задом наперёд", raw: "303-301", wantErr: true},
+		{name: "не число", raw: "abc", wantErr: true},
+		{name: "не число в границе диапазона", raw: "200-abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRanges(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseRanges(%q): ожидалась ошибка, получили %v", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRanges(%q): неожиданная ошибка: %v", tt.raw, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseRanges(%q) = %v, ожидали %v", tt.raw, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("parseRanges(%q)[%d] = %v, ожидали %v", tt.raw, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRangesContain(t *testing.T) {
+	ranges, err := parseRanges("200,301-303")
+	if err != nil {
+		t.Fatalf("parseRanges: %v", err)
+	}
+
+	tests := []struct {
+		v    int64
+		want bool
+	}{
+		{200, true},
+		{301, true},
+		{302, true},
+		{303, true},
+		{304, false},
+		{199, false},
+	}
+	for _, tt := range tests {
+		if got := rangesContain(ranges, tt.v); got != tt.want {
+			t.Errorf("rangesContain(%v) = %v, ожидали %v", tt.v, got, tt.want)
+		}
+	}
+}
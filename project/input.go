@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// openURLSource открывает источник URL: обычный файл или, для "-", stdin
+func openURLSource(filename string) (io.ReadCloser, error) {
+	if filename == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(filename)
+}
+
+// normalizeURL добавляет https://, если в строке нет схемы
+func normalizeURL(u string) string {
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return "https://" + u
+	}
+	return u
+}
+
+// readLines построчно читает непустые строки из reader (используется для -wordlist и
+// базовых URL в -mode dir, где развёртывание по портам/IP не требуется)
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// streamTargets построчно читает URL из reader, разворачивает каждый через expandTarget
+// (-ports/-probe-all-ips) и отправляет результат в targetChan. Используется как для обычных
+// файлов, так и для "-"/stdin, что позволяет обрабатывать списки, не помещающиеся в память.
+func streamTargets(r io.Reader, ports []string, probeAllIPs bool, targetChan chan<- Target) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		for _, target := range expandTarget(normalizeURL(line), ports, probeAllIPs) {
+			targetChan <- target
+		}
+	}
+	return scanner.Err()
+}
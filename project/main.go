@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -15,78 +14,71 @@ import (
 	"time"
 )
 
-// Result хранит все данные по одному URL
-type Result struct {
-	URL        string
-	StatusCode int
-	OK         bool
-	TTFBMs     int64
-	SizeBytes  int64
-	Contains   *bool
-	Error      string
-}
-
-// readURLs читает URL из файла и возвращает их список
-func readURLs(filename string) ([]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var urls []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		url := strings.TrimSpace(scanner.Text())
-		if url != "" {
-			urls = append(urls, url)
+// parseHeaders разбирает повторяющиеся флаги -H "Key: Value" в http.Header
+func parseHeaders(raw []string) http.Header {
+	h := http.Header{}
+	for _, line := range raw {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
 		}
+		h.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
 	}
-	return urls, scanner.Err()
+	return h
 }
 
-// checkURL проверяет один URL и возвращает заполненную структуру Result
-func checkURL(url string, client *http.Client, contains string) Result {
-	res := Result{URL: url}
-	start := time.Now()
-
-	// Выполняем запрос
-	resp, err := client.Get(url)
-	if err != nil {
-		res.Error = err.Error()
-		return res
-	}
-	defer resp.Body.Close()
-
-	// TTFB – время до получения заголовков
-	res.TTFBMs = time.Since(start).Milliseconds()
-	res.StatusCode = resp.StatusCode
-	res.OK = resp.StatusCode >= 200 && resp.StatusCode < 300
+// headerFlags реализует flag.Value для повторяющегося флага -H
+type headerFlags []string
 
-	// Читаем тело для подсчёта байт и поиска подстроки
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		res.Error = err.Error()
-		return res
-	}
-	res.SizeBytes = int64(len(body))
-
-	if contains != "" {
-		found := strings.Contains(string(body), contains)
-		res.Contains = &found
-	}
-	return res
+func (h *headerFlags) String() string { return strings.Join(*h, ", ") }
+func (h *headerFlags) Set(v string) error {
+	*h = append(*h, v)
+	return nil
 }
 
 func main() {
 	// Флаги командной строки (стандартные)
-	file := flag.String("file", "urls.txt", "файл со списком URL")
+	file := flag.String("file", "urls.txt", "файл со списком URL ('-' читает построчно из stdin)")
+	stdin := flag.Bool("stdin", false, "читать URL построчно из stdin вместо -file")
 	workers := flag.Int("workers", 5, "количество воркеров")
 	contains := flag.String("contains", "", "подстрока для поиска в теле ответа")
 	timeout := flag.Duration("timeout", 10*time.Second, "таймаут HTTP-запроса")
+	method := flag.String("method", "GET", "HTTP-метод запроса")
+	body := flag.String("body", "", "тело запроса")
+	followRedirects := flag.Bool("follow-redirects", false, "следовать по редиректам, записывая цепочку")
+	tlsProbe := flag.Bool("tls-probe", false, "отдельно дозваниваться по tls.Dial за сертификатом, если ответ не TLS")
+	ports := flag.String("ports", "", "список портов через запятую для проверки каждого хоста")
+	probeAllIPs := flag.Bool("probe-all-ips", false, "резолвить хост и пробировать каждый A/AAAA-адрес отдельно")
+	hashAlgo := flag.String("hash-algo", "sha256", "алгоритм хеширования тела ответа: sha256, sha1 или md5")
+
+	verbose := flag.Bool("verbose", false, "добавить в таблицу разбивку времени (DNS/Connect/TLS) и Server/Title")
+	csvOutput := flag.String("csv", "", "файл для сохранения результатов в формате CSV")
+
+	rate := flag.Float64("rate", 0, "ограничение запросов в секунду (0 — без ограничения)")
+	retries := flag.Int("retries", 0, "число повторов при сетевых ошибках, 429 и 5xx с экспоненциальным backoff")
+	maxPerHost := flag.Int("max-per-host", 0, "максимум одновременных запросов к одному хосту (0 — без ограничения)")
+
+	matchStatus := flag.String("match-status", "", "показывать только статусы из списка/диапазона, например 200,301-303")
+	filterStatus := flag.String("filter-status", "", "скрывать статусы из списка/диапазона")
+	matchSize := flag.String("match-size", "", "показывать только размеры тела из списка/диапазона в байтах")
+	filterSize := flag.String("filter-size", "", "скрывать размеры тела из списка/диапазона в байтах")
+	matchRegex := flag.String("match-regex", "", "показывать только результаты, где <title> или заголовки совпадают с regex")
+	filterRegex := flag.String("filter-regex", "", "скрывать результаты, где <title> или заголовки совпадают с regex")
+	matchString := flag.String("match-string", "", "показывать только результаты, содержащие одну из подстрок (через запятую)")
+	filterString := flag.String("filter-string", "", "скрывать результаты, содержащие одну из подстрок (через запятую)")
+
+	mode := flag.String("mode", "probe", "режим работы: probe (проверка URL) или dir (перебор путей по словарю)")
+	wordlist := flag.String("wordlist", "", "файл со словарём путей для -mode dir")
+	extensions := flag.String("extensions", "", "список расширений через запятую для -mode dir, например .php,.html")
+	statusCodes := flag.String("status-codes", "200,204,301,302,307,401,403", "статусы, которые считаются найденным путём в -mode dir")
+
+	var headers headerFlags
+	flag.Var(&headers, "H", "дополнительный заголовок запроса в формате 'Key: Value' (можно указывать несколько раз)")
 
 	// Ручной разбор --json (с опциональным значением)
 	var jsonOutput string
+	var jsonlOutput string
+	jsonlSet := false
 	args := os.Args[1:]
 	newArgs := []string{}
 	skip := false
@@ -109,55 +101,159 @@ func main() {
 			jsonOutput = strings.TrimPrefix(arg, "--json=")
 			continue
 		}
+		if arg == "--jsonl" {
+			// Без значения пишем NDJSON в stdout
+			jsonlSet = true
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				jsonlOutput = args[i+1]
+				skip = true
+			}
+			continue
+		}
+		if strings.HasPrefix(arg, "--jsonl=") {
+			jsonlSet = true
+			jsonlOutput = strings.TrimPrefix(arg, "--jsonl=")
+			continue
+		}
 		newArgs = append(newArgs, arg)
 	}
 	// Подменяем os.Args для flag.Parse
 	os.Args = append([]string{os.Args[0]}, newArgs...)
 	flag.Parse()
 
-	urls, err := readURLs(*file)
+	mfCfg, err := buildMatchFilterConfig(*matchStatus, *filterStatus, *matchSize, *filterSize,
+		*matchRegex, *filterRegex, *matchString, *filterString)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Ошибка чтения файла: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Ошибка разбора match/filter флагов: %v\n", err)
 		os.Exit(1)
 	}
-	if len(urls) == 0 {
-		fmt.Fprintln(os.Stderr, "Файл не содержит URL")
+
+	sourceName := *file
+	if *stdin {
+		sourceName = "-"
+	}
+	src, err := openURLSource(sourceName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка чтения файла: %v\n", err)
 		os.Exit(1)
 	}
+	defer src.Close()
+
+	cfg := ProbeConfig{
+		Method:          strings.ToUpper(*method),
+		Headers:         parseHeaders(headers),
+		Body:            *body,
+		FollowRedirects: *followRedirects,
+		Contains:        *contains,
+		TLSProbe:        *tlsProbe,
+		Ports:           parsePorts(*ports),
+		HashAlgo:        *hashAlgo,
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	limiter := NewRateLimiter(*rate)
+	hostLimiter := NewHostLimiter(*maxPerHost)
+	rc := RetryConfig{MaxRetries: *retries, BaseDelay: 500 * time.Millisecond}
+
+	if *mode == "dir" {
+		if *wordlist == "" {
+			fmt.Fprintln(os.Stderr, "-mode dir требует -wordlist")
+			os.Exit(1)
+		}
+		wordlistFile, err := os.Open(*wordlist)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка чтения словаря: %v\n", err)
+			os.Exit(1)
+		}
+		words, err := readLines(wordlistFile)
+		wordlistFile.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка чтения словаря: %v\n", err)
+			os.Exit(1)
+		}
 
-	// Добавляем https:// если нет схемы
-	for i, u := range urls {
-		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
-			urls[i] = "https://" + u
+		baseURLs, err := readLines(src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка чтения URL: %v\n", err)
+			os.Exit(1)
 		}
+		for i, u := range baseURLs {
+			baseURLs[i] = normalizeURL(u)
+		}
+
+		statusRanges, err := parseRanges(*statusCodes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка разбора -status-codes: %v\n", err)
+			os.Exit(1)
+		}
+
+		results := runDirMode(baseURLs, words, parseStringList(*extensions), statusRanges, *workers, client, cfg, rc, limiter, hostLimiter)
+
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].URL < results[j].URL
+		})
+		results = filterResults(results, mfCfg)
+
+		if jsonlSet {
+			ch := make(chan Result, len(results))
+			for _, r := range results {
+				ch <- r
+			}
+			close(ch)
+			if err := writeJSONL(ch, MatchFilterConfig{}, jsonlOutput); err != nil {
+				fmt.Fprintf(os.Stderr, "Ошибка записи NDJSON: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		printTable(results, *contains != "", *verbose)
+		if jsonOutput != "" {
+			saveJSON(results, jsonOutput)
+		}
+		if *csvOutput != "" {
+			if err := saveCSV(results, *csvOutput); err != nil {
+				fmt.Fprintf(os.Stderr, "Ошибка записи CSV-файла: %v\n", err)
+			}
+		}
+		return
 	}
 
-	urlChan := make(chan string, len(urls))
-	resultChan := make(chan Result, len(urls))
+	urlChan := make(chan Target, *workers*4)
+	resultChan := make(chan Result, *workers*4)
 
 	var wg sync.WaitGroup
-	client := &http.Client{Timeout: *timeout}
 
 	for i := 0; i < *workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for url := range urlChan {
-				resultChan <- checkURL(url, client, *contains)
+			for t := range urlChan {
+				resultChan <- checkURLWithRetry(t, client, cfg, rc, limiter, hostLimiter)
 			}
 		}()
 	}
 
-	for _, u := range urls {
-		urlChan <- u
-	}
-	close(urlChan)
+	go func() {
+		defer close(urlChan)
+		if err := streamTargets(src, cfg.Ports, *probeAllIPs, urlChan); err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка чтения URL: %v\n", err)
+		}
+	}()
 
 	go func() {
 		wg.Wait()
 		close(resultChan)
 	}()
 
+	if jsonlSet {
+		if err := writeJSONL(resultChan, mfCfg, jsonlOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка записи NDJSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var results []Result
 	for res := range resultChan {
 		results = append(results, res)
@@ -167,20 +263,31 @@ func main() {
 		return results[i].URL < results[j].URL
 	})
 
-	printTable(results, *contains != "")
+	results = filterResults(results, mfCfg)
+
+	printTable(results, *contains != "", *verbose)
 
 	if jsonOutput != "" {
 		saveJSON(results, jsonOutput)
 	}
+	if *csvOutput != "" {
+		if err := saveCSV(results, *csvOutput); err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка записи CSV-файла: %v\n", err)
+		}
+	}
 }
 
 // printTable выводит красиво отформатированную таблицу через tabwriter
-func printTable(results []Result, showContains bool) {
+func printTable(results []Result, showContains, verbose bool) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	defer w.Flush()
 
 	// Заголовок
-	fmt.Fprintln(w, "URL\tСтатус\tOK\tTTFB(ms)\tБайты\tСодержит\tОшибка")
+	header := "URL\tСтатус\tOK\tTTFB(ms)\tБайты\tСодержит\tОшибка"
+	if verbose {
+		header += "\tDNS(ms)\tConnect(ms)\tTLS(ms)\tВсего(ms)\tServer\tTitle"
+	}
+	fmt.Fprintln(w, header)
 	fmt.Fprintln(w, strings.Repeat("-", 80))
 
 	// Строки
@@ -197,9 +304,39 @@ func printTable(results []Result, showContains bool) {
 		if r.Contains != nil {
 			containsVal = fmt.Sprintf("%t", *r.Contains)
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
-			r.URL, status, ok, r.TTFBMs, r.SizeBytes, containsVal, r.Error)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s",
+			r.URL, status, ok, r.Timing.TTFBMs, r.SizeBytes, containsVal, r.Error)
+		if verbose {
+			fmt.Fprintf(w, "\t%d\t%d\t%d\t%d\t%s\t%s",
+				r.Timing.DNSMs, r.Timing.ConnectMs, r.Timing.TLSMs, r.Timing.TotalMs, r.Server, r.Title)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// writeJSONL пишет каждый Result отдельной строкой JSON сразу после того, как он готов,
+// не дожидаясь остальных — для композиции с jq и других потоковых пайплайнов
+func writeJSONL(resultChan <-chan Result, cfg MatchFilterConfig, filename string) error {
+	out := io.Writer(os.Stdout)
+	if filename != "" {
+		f, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
+	for res := range resultChan {
+		if !cfg.passes(res) {
+			continue
+		}
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // saveJSON сохраняет результаты в формате JSON с отступами
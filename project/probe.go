@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProbeConfig описывает параметры, с которыми пробируется каждый URL
+type ProbeConfig struct {
+	Method          string
+	Headers         http.Header
+	Body            string
+	FollowRedirects bool
+	Contains        string
+	TLSProbe        bool
+	Ports           []string
+	HashAlgo        string
+}
+
+// Target описывает один URL для проверки вместе с необязательным IP, по которому
+// нужно дозваниваться вместо обычного резолвинга хоста (-probe-all-ips). Хост и
+// Host-заголовок/TLS SNI при этом остаются прежними, чтобы не ломать виртуальный хостинг.
+type Target struct {
+	URL    string
+	DialIP string
+}
+
+var titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// checkURL проверяет одну цель и возвращает заполненную структуру Result
+func checkURL(t Target, client *http.Client, cfg ProbeConfig) (res Result) {
+	res = Result{URL: t.URL, Method: cfg.Method, IP: t.DialIP}
+	start := time.Now()
+
+	parsedURL, perr := url.Parse(t.URL)
+	if perr == nil {
+		res.Port = portOf(parsedURL)
+	}
+
+	trace, timing := newTimingTrace()
+	defer func() {
+		timing.TotalMs = time.Since(start).Milliseconds()
+		res.Timing = *timing
+	}()
+
+	// -tls-probe должен сработать даже если основной запрос не удался (например,
+	// http://host с закрытым 80 портом и поднятым на 443 TLS) — поэтому навешиваем
+	// его отдельным defer'ом, а не только на пути успешного не-TLS ответа.
+	if cfg.TLSProbe {
+		defer func() {
+			if res.TLS == nil {
+				if info, err := tlsProbeHost(t); err == nil {
+					res.TLS = info
+				}
+			}
+		}()
+	}
+
+	req, err := http.NewRequest(cfg.Method, t.URL, bodyReader(cfg.Body))
+	if err != nil {
+		res.Error = err.Error()
+		return
+	}
+	for k, vals := range cfg.Headers {
+		for _, v := range vals {
+			req.Header.Add(k, v)
+		}
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	var redirects []string
+	httpClient := *client
+	if t.DialIP != "" && parsedURL != nil {
+		httpClient.Transport = dialOverrideTransport(client.Transport, t.DialIP, res.Port, parsedURL.Hostname())
+	}
+	if !cfg.FollowRedirects {
+		httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	} else {
+		httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			redirects = append(redirects, req.URL.String())
+			return nil
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		res.Error = err.Error()
+		return
+	}
+	defer resp.Body.Close()
+
+	timing.TTFBMs = time.Since(start).Milliseconds()
+	res.StatusCode = resp.StatusCode
+	res.OK = resp.StatusCode >= 200 && resp.StatusCode < 300
+	res.Proto = resp.Proto
+	res.ContentType = resp.Header.Get("Content-Type")
+	res.Server = resp.Header.Get("Server")
+	res.PoweredBy = resp.Header.Get("X-Powered-By")
+	res.ResponseHeaders = flattenHeader(resp.Header)
+	res.RedirectChain = redirects
+	res.FinalURL = resp.Request.URL.String()
+
+	if resp.TLS != nil {
+		res.TLS = certInfoFromConnState(resp.TLS)
+	}
+
+	// Читаем тело для подсчёта байт, поиска подстроки, заголовка <title> и хэша
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		res.Error = err.Error()
+		return
+	}
+	res.SizeBytes = int64(len(body))
+	res.Title = extractTitle(body)
+	res.BodyHash = hashBody(body, cfg.HashAlgo)
+
+	if cfg.Contains != "" {
+		found := strings.Contains(string(body), cfg.Contains)
+		res.Contains = &found
+	}
+
+	return
+}
+
+// portOf возвращает порт из URL, подставляя 443/80 по умолчанию в зависимости от схемы
+func portOf(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if p := u.Port(); p != "" {
+		return p
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// dialOverrideTransport клонирует транспорт клиента (или http.DefaultTransport, если клиент
+// его не задаёт) и подменяет адрес соединения на ip:port, сохраняя при этом sni в качестве
+// TLS ServerName — так -probe-all-ips дозванивается до конкретного адреса, не ломая
+// проверку сертификата и виртуальный хостинг по исходному имени хоста
+func dialOverrideTransport(base http.RoundTripper, ip, port, sni string) http.RoundTripper {
+	bt, ok := base.(*http.Transport)
+	if !ok || bt == nil {
+		bt = http.DefaultTransport.(*http.Transport)
+	}
+	bt = bt.Clone()
+
+	dialAddr := net.JoinHostPort(ip, port)
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	bt.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, dialAddr)
+	}
+
+	if bt.TLSClientConfig == nil {
+		bt.TLSClientConfig = &tls.Config{}
+	} else {
+		bt.TLSClientConfig = bt.TLSClientConfig.Clone()
+	}
+	bt.TLSClientConfig.ServerName = sni
+
+	return bt
+}
+
+// bodyReader превращает строку тела запроса в io.Reader, пригодный для http.NewRequest
+func bodyReader(body string) io.Reader {
+	if body == "" {
+		return nil
+	}
+	return bytes.NewBufferString(body)
+}
+
+// flattenHeader сворачивает http.Header в map[string]string (по одному значению на ключ)
+func flattenHeader(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k := range h {
+		out[k] = h.Get(k)
+	}
+	return out
+}
+
+// extractTitle вытаскивает содержимое <title> из HTML-тела ответа
+func extractTitle(body []byte) string {
+	m := titleRe.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(string(m[1]))
+}
+
+// hashBody считает хэш тела ответа алгоритмом algo (sha256, sha1 или md5; по умолчанию
+// sha256) и возвращает его в hex-виде
+func hashBody(body []byte, algo string) string {
+	switch algo {
+	case "sha1":
+		sum := sha1.Sum(body)
+		return hex.EncodeToString(sum[:])
+	case "md5":
+		sum := md5.Sum(body)
+		return hex.EncodeToString(sum[:])
+	default:
+		sum := sha256.Sum256(body)
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// certInfoFromConnState извлекает CN/SAN/issuer/срок действия из состояния TLS-соединения
+func certInfoFromConnState(state *tls.ConnectionState) *TLSCertInfo {
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	cert := state.PeerCertificates[0]
+	return &TLSCertInfo{
+		CommonName: cert.Subject.CommonName,
+		SANs:       cert.DNSNames,
+		Issuer:     cert.Issuer.CommonName,
+		NotBefore:  cert.NotBefore,
+		NotAfter:   cert.NotAfter,
+	}
+}
+
+// tlsProbeHost устанавливает отдельное TLS-соединение с хостом из URL и возвращает данные
+// сертификата; если у t задан DialIP, дозванивается по нему, но SNI/ServerName оставляет
+// хостом из URL
+func tlsProbeHost(t Target) (*TLSCertInfo, error) {
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		// Порт в URL не указан: пробуем 443 независимо от схемы, а не порт по
+		// умолчанию для схемы (80 для http) — именно ради схемы "http://host,
+		// 80 закрыт, но 443 поднимает TLS" и существует -tls-probe.
+		port = "443"
+	}
+
+	dialHost := host
+	if t.DialIP != "" {
+		dialHost = t.DialIP
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(dialHost, port), &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	return certInfoFromConnState(&state), nil
+}
+
+// resolveIPs резолвит хост в список IP-адресов (A/AAAA) для режима -probe-all-ips
+func resolveIPs(host string) ([]string, error) {
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		out = append(out, ip.String())
+	}
+	return out, nil
+}
+
+// withPort подменяет порт в URL, не трогая хост, сохраняя остальную часть URL
+func withPort(target, port string) (string, error) {
+	if port == "" {
+		return target, nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), port)
+	return u.String(), nil
+}
+
+// expandTarget разворачивает один URL в список целей для проверки с учётом
+// -ports (проверка каждого порта) и -probe-all-ips (проверка каждого A/AAAA-адреса).
+// Хост в самом URL не меняется — для -probe-all-ips IP кладётся в Target.DialIP, чтобы
+// дозвон шёл по нему, а Host-заголовок и TLS SNI остались исходным именем хоста.
+func expandTarget(u string, ports []string, probeAllIPs bool) []Target {
+	if len(ports) == 0 && !probeAllIPs {
+		return []Target{{URL: u}}
+	}
+
+	ips := []string{""}
+	if probeAllIPs {
+		parsed, err := url.Parse(u)
+		if err == nil {
+			if resolved, err := resolveIPs(parsed.Hostname()); err == nil && len(resolved) > 0 {
+				ips = resolved
+			}
+		}
+	}
+
+	portList := ports
+	if len(portList) == 0 {
+		portList = []string{""}
+	}
+
+	var targets []Target
+	for _, ip := range ips {
+		for _, port := range portList {
+			target, err := withPort(u, port)
+			if err != nil {
+				continue
+			}
+			targets = append(targets, Target{URL: target, DialIP: ip})
+		}
+	}
+	return targets
+}
+
+// parsePorts разбирает список портов через запятую, переданный в -ports
+func parsePorts(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	ports := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(p); err != nil {
+			continue
+		}
+		ports = append(ports, p)
+	}
+	return ports
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter — это golang.org/x/time/rate.Limiter, используемый общим экземпляром на
+// всех воркеров, как -rate. nil означает, что лимит отключён.
+type RateLimiter = rate.Limiter
+
+// NewRateLimiter создаёт RateLimiter на rps запросов в секунду с всплеском в rps
+// запросов; rps <= 0 отключает лимит
+func NewRateLimiter(rps float64) *RateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	burst := int(rps)
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// waitRateLimit блокируется, пока лимитер не выдаст токен; nil-лимитер — не-op
+func waitRateLimit(limiter *RateLimiter) {
+	if limiter == nil {
+		return
+	}
+	_ = limiter.Wait(context.Background())
+}
+
+// HostLimiter ограничивает число одновременных запросов к одному хосту (-max-per-host)
+type HostLimiter struct {
+	max  int
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+// NewHostLimiter создаёт HostLimiter с лимитом max на хост; max <= 0 отключает лимит
+func NewHostLimiter(max int) *HostLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &HostLimiter{max: max, sems: make(map[string]chan struct{})}
+}
+
+func (h *HostLimiter) semFor(host string) chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.sems[host]
+	if !ok {
+		s = make(chan struct{}, h.max)
+		h.sems[host] = s
+	}
+	return s
+}
+
+// Acquire занимает один из max слотов для host, блокируясь, если все заняты
+func (h *HostLimiter) Acquire(host string) {
+	if h == nil {
+		return
+	}
+	h.semFor(host) <- struct{}{}
+}
+
+// Release освобождает слот, занятый Acquire
+func (h *HostLimiter) Release(host string) {
+	if h == nil {
+		return
+	}
+	<-h.semFor(host)
+}
+
+// RetryConfig описывает поведение -retries: сколько раз повторять и базовую задержку
+// экспоненциального backoff
+type RetryConfig struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+}
+
+// isTransient сообщает, стоит ли повторять запрос, давший такой результат
+func isTransient(r Result) bool {
+	if r.Error != "" {
+		return true
+	}
+	if r.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return r.StatusCode >= 500 && r.StatusCode < 600
+}
+
+// retryAfterDelay читает заголовок Retry-After (секунды или HTTP-дата) из ответа
+func retryAfterDelay(r Result) (time.Duration, bool) {
+	v := r.ResponseHeaders["Retry-After"]
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// backoffDelay считает экспоненциальную задержку с джиттером для номера попытки attempt
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	d := base * time.Duration(uint(1)<<uint(attempt-1))
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d + jitter
+}
+
+// hostOf возвращает хост (без порта) из URL, используемый ключом для HostLimiter
+func hostOf(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	return u.Hostname()
+}
+
+// checkURLWithRetry оборачивает checkURL повторами с backoff при транзиентных ошибках,
+// соблюдая -rate и -max-per-host, и записывает Attempts/RetriedAfterMs в результат
+func checkURLWithRetry(t Target, client *http.Client, cfg ProbeConfig, rc RetryConfig, limiter *RateLimiter, hostLimiter *HostLimiter) Result {
+	host := hostOf(t.URL)
+	var res Result
+	var retriedMs int64
+
+	for attempt := 1; attempt <= rc.MaxRetries+1; attempt++ {
+		waitRateLimit(limiter)
+		hostLimiter.Acquire(host)
+		res = checkURL(t, client, cfg)
+		hostLimiter.Release(host)
+		res.Attempts = attempt
+
+		if attempt == rc.MaxRetries+1 || !isTransient(res) {
+			break
+		}
+
+		delay, ok := retryAfterDelay(res)
+		if !ok {
+			delay = backoffDelay(rc.BaseDelay, attempt)
+		}
+		retriedMs += delay.Milliseconds()
+		time.Sleep(delay)
+	}
+
+	res.RetriedAfterMs = retriedMs
+	return res
+}
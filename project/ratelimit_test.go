@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	base := 500 * time.Millisecond
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{1, base, base + base/2},
+		{2, 2 * base, 2*base + base},
+		{3, 4 * base, 4*base + 2*base},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			d := backoffDelay(base, tt.attempt)
+			if d < tt.min || d > tt.max {
+				t.Fatalf("backoffDelay(%v, %d) = %v, ожидали диапазон [%v, %v]", base, tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	t.Run("заголовок отсутствует", func(t *testing.T) {
+		r := Result{ResponseHeaders: map[string]string{}}
+		if _, ok := retryAfterDelay(r); ok {
+			t.Fatalf("ожидали ok=false при отсутствии Retry-After")
+		}
+	})
+
+	t.Run("секунды", func(t *testing.T) {
+		r := Result{ResponseHeaders: map[string]string{"Retry-After": "5"}}
+		d, ok := retryAfterDelay(r)
+		if !ok || d != 5*time.Second {
+			t.Fatalf("retryAfterDelay = %v, %v, ожидали 5s, true", d, ok)
+		}
+	})
+
+	t.Run("HTTP-дата в будущем", func(t *testing.T) {
+		future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+		r := Result{ResponseHeaders: map[string]string{"Retry-After": future}}
+		d, ok := retryAfterDelay(r)
+		if !ok {
+			t.Fatalf("ожидали ok=true для корректной HTTP-даты")
+		}
+		if d <= 0 || d > 10*time.Second {
+			t.Fatalf("retryAfterDelay для будущей даты = %v, ожидали положительную задержку до 10s", d)
+		}
+	})
+
+	t.Run("HTTP-дата в прошлом", func(t *testing.T) {
+		past := time.Now().Add(-10 * time.Second).UTC().Format(http.TimeFormat)
+		r := Result{ResponseHeaders: map[string]string{"Retry-After": past}}
+		d, ok := retryAfterDelay(r)
+		if !ok || d != 0 {
+			t.Fatalf("retryAfterDelay для прошедшей даты = %v, %v, ожидали 0, true", d, ok)
+		}
+	})
+
+	t.Run("мусор", func(t *testing.T) {
+		r := Result{ResponseHeaders: map[string]string{"Retry-After": "не дата и не число"}}
+		if _, ok := retryAfterDelay(r); ok {
+			t.Fatalf("ожидали ok=false для нераспознанного значения Retry-After")
+		}
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Result
+		want bool
+	}{
+		{"сетевая ошибка", Result{Error: "dial tcp: connection refused"}, true},
+		{"429", Result{StatusCode: http.StatusTooManyRequests}, true},
+		{"500", Result{StatusCode: 500}, true},
+		{"599", Result{StatusCode: 599}, true},
+		{"200", Result{StatusCode: 200}, false},
+		{"404", Result{StatusCode: 404}, false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.r); got != tt.want {
+			t.Errorf("isTransient(%q) = %v, ожидали %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import "time"
+
+// TLSCertInfo хранит сведения о сертификате, полученные при TLS-пробе
+type TLSCertInfo struct {
+	CommonName string    `json:"common_name,omitempty"`
+	SANs       []string  `json:"sans,omitempty"`
+	Issuer     string    `json:"issuer,omitempty"`
+	NotBefore  time.Time `json:"not_before,omitempty"`
+	NotAfter   time.Time `json:"not_after,omitempty"`
+}
+
+// Timing хранит разбивку времени запроса по стадиям, снятую через net/http/httptrace
+type Timing struct {
+	DNSMs     int64 `json:"dns_ms"`
+	ConnectMs int64 `json:"connect_ms"`
+	TLSMs     int64 `json:"tls_ms,omitempty"`
+	TTFBMs    int64 `json:"ttfb_ms"`
+	TotalMs   int64 `json:"total_ms"`
+}
+
+// Result хранит все данные по одному URL. Теги JSON — snake_case, как у Timing и
+// TLSCertInfo, чтобы один -json/-jsonl документ грузился в таблицы/jq единообразно.
+type Result struct {
+	URL        string `json:"url"`
+	Method     string `json:"method"`
+	IP         string `json:"ip,omitempty"`
+	Port       string `json:"port,omitempty"`
+	BaseURL    string `json:"base_url,omitempty"`
+	Path       string `json:"path,omitempty"`
+	StatusCode int    `json:"status_code"`
+	OK         bool   `json:"ok"`
+	Timing     Timing `json:"timing"`
+	SizeBytes  int64  `json:"size_bytes"`
+	Contains   *bool  `json:"contains"`
+	Error      string `json:"error"`
+
+	Attempts       int   `json:"attempts,omitempty"`
+	RetriedAfterMs int64 `json:"retried_after_ms,omitempty"`
+
+	Proto           string            `json:"proto,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	Server          string            `json:"server,omitempty"`
+	PoweredBy       string            `json:"powered_by,omitempty"`
+	Title           string            `json:"title,omitempty"`
+	ContentType     string            `json:"content_type,omitempty"`
+	BodyHash        string            `json:"body_hash,omitempty"`
+
+	RedirectChain []string `json:"redirect_chain,omitempty"`
+	FinalURL      string   `json:"final_url,omitempty"`
+
+	TLS *TLSCertInfo `json:"tls,omitempty"`
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"time"
+)
+
+// newTimingTrace строит httptrace.ClientTrace, заполняющий Timing по мере прохождения
+// стадий запроса: DNS, TCP-соединение, TLS-рукопожатие
+func newTimingTrace() (*httptrace.ClientTrace, *Timing) {
+	timing := &Timing{}
+	var dnsStart, connectStart, tlsStart time.Time
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if !dnsStart.IsZero() {
+				timing.DNSMs = time.Since(dnsStart).Milliseconds()
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if !connectStart.IsZero() {
+				timing.ConnectMs = time.Since(connectStart).Milliseconds()
+			}
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if !tlsStart.IsZero() {
+				timing.TLSMs = time.Since(tlsStart).Milliseconds()
+			}
+		},
+	}
+
+	return trace, timing
+}